@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/people/v1"
+
+	"github.com/bilbo-22/gogcli-gateway/internal/googleapi"
+)
+
+// newPeopleService builds the People API client for the resolved provider,
+// handing it the gateway RoundTripper for that provider (Google's n8n
+// webhook by default, or another provider's transport when --provider/
+// GOG_PROVIDER selects one).
+//
+// Incomplete: this tree has no root/auth command source (no cobra command
+// registers a --provider flag, and nothing constructs a People client
+// today), so nothing calls newPeopleService yet. It is the real entry point
+// a root command should call once that source lands; wiring it in is
+// tracked as follow-up work against whichever commit introduces the root
+// command, not done here.
+func newPeopleService(ctx context.Context, providerFlag string) (*people.Service, error) {
+	provider, err := googleapi.ResolveProvider(resolveProviderName(providerFlag))
+	if err != nil {
+		return nil, err
+	}
+
+	rt := provider.NewTransport(googleapi.ProviderConfig{
+		WebhookURL: googleapi.WebhookURLFromEnv(),
+		Secret:     googleapi.WebhookSecretFromEnv(),
+	})
+
+	svc, err := people.NewService(ctx, option.WithHTTPClient(&http.Client{Transport: rt}))
+	if err != nil {
+		return nil, fmt.Errorf("create people service: %w", err)
+	}
+
+	return svc, nil
+}
+
+// primaryName returns p's display name, preferring the Name marked primary
+// and falling back to "given family" when no DisplayName is set.
+func primaryName(p *people.Person) string {
+	if p == nil {
+		return ""
+	}
+
+	var best *people.Name
+
+	for _, n := range p.Names {
+		if n == nil {
+			continue
+		}
+
+		if best == nil {
+			best = n
+		}
+
+		if n.Metadata != nil && n.Metadata.Primary {
+			best = n
+			break
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	if best.DisplayName != "" {
+		return best.DisplayName
+	}
+
+	return strings.TrimSpace(strings.TrimSpace(best.GivenName) + " " + strings.TrimSpace(best.FamilyName))
+}
+
+// primaryEmail returns p's primary email address, or the first one if none
+// is marked primary.
+func primaryEmail(p *people.Person) string {
+	if p == nil {
+		return ""
+	}
+
+	var best *people.EmailAddress
+
+	for _, e := range p.EmailAddresses {
+		if e == nil {
+			continue
+		}
+
+		if best == nil {
+			best = e
+		}
+
+		if e.Metadata != nil && e.Metadata.Primary {
+			best = e
+			break
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return best.Value
+}
+
+// primaryPhone returns p's primary phone number, or the first one if none
+// is marked primary.
+func primaryPhone(p *people.Person) string {
+	if p == nil {
+		return ""
+	}
+
+	var best *people.PhoneNumber
+
+	for _, n := range p.PhoneNumbers {
+		if n == nil {
+			continue
+		}
+
+		if best == nil {
+			best = n
+		}
+
+		if n.Metadata != nil && n.Metadata.Primary {
+			best = n
+			break
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return best.Value
+}
+
+// primaryBirthday returns p's primary birthday formatted as "YYYY-MM-DD",
+// "MM-DD", or "YYYY" depending on which Date fields are set, or the raw Text
+// when the birthday carries no structured Date.
+func primaryBirthday(p *people.Person) string {
+	if p == nil {
+		return ""
+	}
+
+	var best *people.Birthday
+
+	for _, b := range p.Birthdays {
+		if b == nil {
+			continue
+		}
+
+		if best == nil {
+			best = b
+		}
+
+		if b.Metadata != nil && b.Metadata.Primary {
+			best = b
+			break
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	if best.Date != nil {
+		return formatBirthdayDate(best.Date)
+	}
+
+	return best.Text
+}
+
+func formatBirthdayDate(d *people.Date) string {
+	switch {
+	case d.Year != 0 && d.Month != 0 && d.Day != 0:
+		return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+	case d.Year != 0:
+		return fmt.Sprintf("%04d", d.Year)
+	case d.Month != 0 && d.Day != 0:
+		return fmt.Sprintf("%02d-%02d", d.Month, d.Day)
+	default:
+		return ""
+	}
+}
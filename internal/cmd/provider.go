@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/bilbo-22/gogcli-gateway/internal/googleapi"
+	"github.com/bilbo-22/gogcli-gateway/internal/secrets"
+)
+
+// providerEnv is consulted when --provider isn't passed explicitly.
+const providerEnv = "GOG_PROVIDER"
+
+// resolveProviderName returns flagValue if set, else GOG_PROVIDER, else
+// googleapi.DefaultProviderName. Callers pass the --provider flag's value.
+//
+// Incomplete: no cobra command in this tree registers a --provider flag,
+// so flagValue has nowhere to come from yet in production; only
+// openTokenStore and newPeopleService call this today, and they themselves
+// aren't wired into a command either. Tracked as follow-up work against
+// whichever commit introduces the root command.
+func resolveProviderName(flagValue string) string {
+	if v := strings.TrimSpace(flagValue); v != "" {
+		return v
+	}
+
+	if v := strings.TrimSpace(os.Getenv(providerEnv)); v != "" {
+		return v
+	}
+
+	return googleapi.DefaultProviderName
+}
+
+// openTokenStore opens the token keyring namespaced to the resolved
+// provider, so the same email authenticated against more than one provider
+// (e.g. Google and GitHub) is stored under distinct keys.
+//
+// Incomplete: same as resolveProviderName, nothing in this tree calls
+// openTokenStore yet; it's the store an auth command should open once one
+// exists. Tracked as follow-up work against whichever commit introduces
+// the root command.
+func openTokenStore(providerFlag string) (*secrets.KeyringStore, error) {
+	return secrets.OpenKeyringStore(resolveProviderName(providerFlag))
+}
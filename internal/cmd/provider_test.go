@@ -0,0 +1,29 @@
+package cmd
+
+import "testing"
+
+func TestResolveProviderName(t *testing.T) {
+	t.Run("flag wins", func(t *testing.T) {
+		t.Setenv(providerEnv, "github")
+
+		if got := resolveProviderName("oidc"); got != "oidc" {
+			t.Fatalf("expected oidc, got %q", got)
+		}
+	})
+
+	t.Run("falls back to env", func(t *testing.T) {
+		t.Setenv(providerEnv, "github")
+
+		if got := resolveProviderName(""); got != "github" {
+			t.Fatalf("expected github, got %q", got)
+		}
+	})
+
+	t.Run("falls back to default", func(t *testing.T) {
+		t.Setenv(providerEnv, "")
+
+		if got := resolveProviderName(""); got != "google" {
+			t.Fatalf("expected google, got %q", got)
+		}
+	})
+}
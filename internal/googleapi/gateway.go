@@ -2,32 +2,109 @@ package googleapi
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-const n8nWebhookURLEnv = "N8N_GOG_WEBHOOK_URL"
+const (
+	n8nWebhookURLEnv    = "N8N_GOG_WEBHOOK_URL"
+	n8nWebhookSecretEnv = "N8N_GOG_WEBHOOK_SECRET"
 
-// WebhookRequest is the JSON payload sent to the n8n webhook.
+	// defaultSignatureWindow bounds how far a response's X-Gog-Timestamp may
+	// drift from now before it is rejected as a possible replay.
+	defaultSignatureWindow = 5 * time.Minute
+)
+
+// Compression modes for WebhookTransport.Compression.
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionAuto = "auto"
+
+	// defaultCompressionThreshold is the request body size, in bytes, above
+	// which CompressionAuto gzips the payload.
+	defaultCompressionThreshold = 1024
+)
+
+// retryAllowedCtxKey is the context key WithRetryAllowed sets.
+type retryAllowedCtxKey struct{}
+
+// WithRetryAllowed marks ctx so WebhookTransport.RoundTrip may retry a
+// non-idempotent request (any method other than GET, HEAD, PUT, or DELETE,
+// e.g. POST or PATCH) on transient failures. GET, HEAD, PUT, and DELETE are
+// always eligible.
+func WithRetryAllowed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryAllowedCtxKey{}, true)
+}
+
+// retryAllowed reports whether a request may be retried: idempotent methods
+// always are, others only when the caller opted in via WithRetryAllowed.
+func retryAllowed(ctx context.Context, method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		allowed, _ := ctx.Value(retryAllowedCtxKey{}).(bool)
+		return allowed
+	}
+}
+
+// streamingCtxKey is the context key WithStreaming sets.
+type streamingCtxKey struct{}
+
+// WithStreaming marks ctx so WebhookTransport.RoundTrip bypasses the JSON
+// envelope and streams the request/response bodies directly through
+// StreamingWebhookURL, for large payloads like Drive downloads or Gmail
+// attachments that shouldn't be buffered whole in memory.
+func WithStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamingCtxKey{}, true)
+}
+
+func streamingRequested(ctx context.Context) bool {
+	streaming, _ := ctx.Value(streamingCtxKey{}).(bool)
+	return streaming
+}
+
+// WebhookRequest is the JSON payload sent to the n8n webhook. Headers is
+// keyed like http.Header, preserving repeated values (e.g. multiple Accept
+// or X-Goog-* entries) instead of collapsing them to one.
 type WebhookRequest struct {
-	Method  string            `json:"method"`
-	URL     string            `json:"url"`
-	Headers map[string]string `json:"headers"`
-	Body    string            `json:"body"`
+	Method    string              `json:"method"`
+	URL       string              `json:"url"`
+	Headers   map[string][]string `json:"headers"`
+	Body      string              `json:"body"`
+	Timestamp int64               `json:"timestamp,omitempty"`
+	// Encoding names the compression applied to Body before base64
+	// encoding, e.g. "gzip". Empty means Body is uncompressed.
+	Encoding string `json:"encoding,omitempty"`
 }
 
-// WebhookResponse is the JSON payload returned by the n8n webhook.
+// WebhookResponse is the JSON payload returned by the n8n webhook. Headers
+// is keyed like http.Header, preserving repeated values such as multiple
+// Set-Cookie entries.
 type WebhookResponse struct {
-	StatusCode int               `json:"status_code"`
-	Headers    map[string]string `json:"headers"`
-	Body       string            `json:"body"`
+	StatusCode int                 `json:"status_code"`
+	Headers    map[string][]string `json:"headers"`
+	Body       string              `json:"body"`
+	// Encoding names the compression applied to Body before base64
+	// encoding, e.g. "gzip". Empty means Body is uncompressed.
+	Encoding string `json:"encoding,omitempty"`
 }
 
 // WebhookTransport implements http.RoundTripper by forwarding requests
@@ -35,6 +112,47 @@ type WebhookResponse struct {
 type WebhookTransport struct {
 	WebhookURL string
 	HTTPClient *http.Client
+
+	// Secret keys HMAC-SHA256 signing of outgoing requests. When empty,
+	// requests are sent unsigned and response signatures are not checked.
+	Secret string
+
+	// SignatureWindow bounds the allowed clock drift between now and a
+	// signed response's timestamp. Zero means defaultSignatureWindow.
+	SignatureWindow time.Duration
+
+	// Compression selects when outgoing request bodies are gzipped:
+	// CompressionNone (default) never compresses, CompressionGzip always
+	// compresses non-empty bodies, CompressionAuto compresses bodies
+	// larger than CompressionThreshold.
+	Compression string
+
+	// CompressionThreshold is the body size, in bytes, above which
+	// CompressionAuto compresses. Zero means defaultCompressionThreshold.
+	CompressionThreshold int
+
+	// Retry configures retry attempts for idempotent requests (and POSTs
+	// marked with WithRetryAllowed) on transient failures. Zero value
+	// fields fall back to DefaultRetryPolicy.
+	Retry RetryPolicy
+
+	// Breaker guards the webhook with a circuit breaker keyed on the
+	// webhook's own host (see breakerHost), since a single n8n instance
+	// typically serves every target API. NewWebhookTransport initializes
+	// it with DefaultBreakerPolicy; breaker() guards against nil for
+	// transports built as struct literals instead.
+	Breaker     *CircuitBreaker
+	breakerOnce sync.Once
+
+	// StreamingPaths lists request URL path prefixes (e.g. "/download/",
+	// "/upload/") that are always streamed through StreamingWebhookURL,
+	// in addition to requests whose context was marked with WithStreaming.
+	StreamingPaths []string
+
+	// StreamingWebhookURL is the companion webhook endpoint used for
+	// streaming requests. Streaming is unavailable (envelope path is used
+	// unconditionally) while this is empty.
+	StreamingWebhookURL string
 }
 
 // NewWebhookTransport creates a WebhookTransport targeting the given webhook URL.
@@ -44,40 +162,358 @@ func NewWebhookTransport(webhookURL string) *WebhookTransport {
 		HTTPClient: &http.Client{
 			Timeout: defaultHTTPTimeout,
 		},
+		Secret:  gatewayWebhookSecret(),
+		Breaker: NewCircuitBreaker(DefaultBreakerPolicy()),
 	}
 }
 
+// signatureWindow returns t.SignatureWindow, falling back to
+// defaultSignatureWindow when unset.
+func (t *WebhookTransport) signatureWindow() time.Duration {
+	if t.SignatureWindow > 0 {
+		return t.SignatureWindow
+	}
+
+	return defaultSignatureWindow
+}
+
+// signPayload computes hex(HMAC-SHA256(secret, timestamp + "." + payload)).
+func signPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// shouldStream reports whether req should bypass the JSON envelope and
+// stream through StreamingWebhookURL instead.
+func (t *WebhookTransport) shouldStream(req *http.Request) bool {
+	if t.StreamingWebhookURL == "" {
+		return false
+	}
+
+	if streamingRequested(req.Context()) {
+		return true
+	}
+
+	for _, prefix := range t.StreamingPaths {
+		if prefix != "" && strings.HasPrefix(req.URL.Path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// breaker returns t.Breaker, initializing it with DefaultBreakerPolicy on
+// first use if nil. Guarded by breakerOnce so concurrent RoundTrip calls on a
+// transport built as a struct literal (rather than via NewWebhookTransport,
+// which already sets Breaker) never race on the field.
+func (t *WebhookTransport) breaker() *CircuitBreaker {
+	t.breakerOnce.Do(func() {
+		if t.Breaker == nil {
+			t.Breaker = NewCircuitBreaker(DefaultBreakerPolicy())
+		}
+	})
+
+	return t.Breaker
+}
+
+// breakerHost returns the host requests to the breaker are keyed on: the
+// webhook's own host, not the target API's. A single n8n instance typically
+// serves every provider's API, so an outage there should fail fast for all
+// of them together rather than isolating failures per target host.
+func (t *WebhookTransport) breakerHost() string {
+	if u, err := url.Parse(t.WebhookURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+
+	return t.WebhookURL
+}
+
+// compressionThreshold returns t.CompressionThreshold, falling back to
+// defaultCompressionThreshold when unset.
+func (t *WebhookTransport) compressionThreshold() int {
+	if t.CompressionThreshold > 0 {
+		return t.CompressionThreshold
+	}
+
+	return defaultCompressionThreshold
+}
+
+// shouldCompress reports whether a request body of the given size should be
+// gzipped, per t.Compression.
+func (t *WebhookTransport) shouldCompress(size int) bool {
+	switch t.Compression {
+	case CompressionGzip:
+		return size > 0
+	case CompressionAuto:
+		return size > t.compressionThreshold()
+	default:
+		return false
+	}
+}
+
+// gzipBytes compresses data with gzip.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// gunzipBytes decompresses gzip-encoded data.
+func gunzipBytes(data []byte) ([]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	defer gr.Close()
+
+	return io.ReadAll(gr)
+}
+
+// responseIsGzipped reports whether a webhook response envelope carries a
+// gzip-compressed body, either via the Encoding field or a Content-Encoding
+// header (as Google itself might set it).
+func responseIsGzipped(resp WebhookResponse) bool {
+	if strings.EqualFold(resp.Encoding, CompressionGzip) {
+		return true
+	}
+
+	for _, v := range resp.Headers["Content-Encoding"] {
+		if strings.EqualFold(v, CompressionGzip) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // RoundTrip implements http.RoundTripper by serializing the request,
-// sending it to the webhook, and reconstructing the response.
+// sending it to the webhook, and reconstructing the response. Idempotent
+// methods (and POSTs marked with WithRetryAllowed) are retried with
+// exponential backoff and jitter on transient failures, guarded by a
+// per-host circuit breaker; see RetryPolicy and BreakerPolicy.
 func (t *WebhookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	// Read and base64-encode the request body.
-	var bodyEncoded string
+	if t.shouldStream(req) {
+		return t.roundTripStreaming(req)
+	}
+
+	var rawBody []byte
 
 	if req.Body != nil {
-		bodyBytes, err := io.ReadAll(req.Body)
+		var err error
+
+		rawBody, err = io.ReadAll(req.Body)
 		if err != nil {
 			return nil, fmt.Errorf("read request body: %w", err)
 		}
 		_ = req.Body.Close()
+	}
+
+	host := t.breakerHost()
+	breaker := t.breaker()
+	allowRetry := retryAllowed(req.Context(), req.Method)
+
+	maxAttempts := 1
+	if allowRetry {
+		maxAttempts = t.Retry.maxAttempts()
+	}
+
+	var lastErr error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if !breaker.Allow(host) {
+			return nil, fmt.Errorf("%w: host %q", ErrCircuitOpen, host)
+		}
+
+		resp, err := t.doRoundTrip(req, rawBody)
+		if err == nil {
+			breaker.RecordSuccess(host)
+			return resp, nil
+		}
+
+		breaker.RecordFailure(host)
+		lastErr = err
+
+		if !allowRetry || attempt == maxAttempts-1 || !isRetryableError(err) {
+			return nil, err
+		}
+
+		delay := t.Retry.backoff(attempt)
+		if ra := retryAfterFrom(err); ra > 0 {
+			delay = ra
+		}
 
-		if len(bodyBytes) > 0 {
-			bodyEncoded = base64.StdEncoding.EncodeToString(bodyBytes)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
 		}
 	}
 
-	// Flatten headers to map[string]string (first value per key).
-	headers := make(map[string]string, len(req.Header))
+	return nil, lastErr
+}
+
+// roundTripStreaming forwards req's body directly to StreamingWebhookURL
+// without buffering it into a JSON envelope, and wires the returned
+// http.Response's Body straight to the webhook's HTTP response reader so
+// large downloads (or uploads) never fully materialize in memory. The
+// original request headers are preserved under an X-Gog-Fwd- prefix and
+// the real target is carried in X-Gog-Target-URL; it does not participate
+// in retries or the circuit breaker.
+//
+// When t.Secret is set, the request is signed and the response signature is
+// verified the same way as the envelope path, but over the method/target
+// URL/status line rather than the body: the body is deliberately never
+// buffered here (that's the point of streaming), so it can't be hashed
+// without reintroducing the memory cost streaming exists to avoid. This
+// authenticates that the companion webhook produced this exact response to
+// this exact request, but — unlike the envelope path — does not detect
+// tampering with the streamed body itself in transit.
+func (t *WebhookTransport) roundTripStreaming(req *http.Request) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(req.Context(), req.Method, t.StreamingWebhookURL, req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("create streaming webhook request: %w", err)
+	}
+
+	httpReq.ContentLength = req.ContentLength
+
 	for k, vals := range req.Header {
-		if len(vals) > 0 {
-			headers[k] = vals[0]
+		for _, v := range vals {
+			httpReq.Header.Add("X-Gog-Fwd-"+k, v)
+		}
+	}
+
+	httpReq.Header.Set("X-Gog-Target-URL", req.URL.String())
+	httpReq.Header.Set("X-Gog-Target-Method", req.Method)
+
+	var ts int64
+	if t.Secret != "" {
+		ts = time.Now().Unix()
+		sig := signPayload(t.Secret, ts, []byte(streamingCanonicalPayload(req.Method, req.URL.String(), 0)))
+		httpReq.Header.Set("X-Gog-Timestamp", strconv.FormatInt(ts, 10))
+		httpReq.Header.Set("X-Gog-Signature", "sha256="+sig)
+	}
+
+	slog.Debug("streaming request via webhook gateway",
+		"method", req.Method,
+		"url", req.URL.String(),
+		"streaming_webhook", t.StreamingWebhookURL)
+
+	httpResp, err := t.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("streaming webhook request: %w", err)
+	}
+
+	if httpResp.StatusCode >= 400 {
+		defer httpResp.Body.Close()
+
+		body, readErr := io.ReadAll(httpResp.Body)
+		if readErr != nil {
+			return nil, fmt.Errorf("read streaming webhook error response: %w", readErr)
+		}
+
+		return nil, &WebhookError{
+			StatusCode: httpResp.StatusCode,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(httpResp.Header.Get("Retry-After")),
 		}
 	}
 
+	if t.Secret != "" {
+		if err := t.verifyStreamingResponseSignature(httpResp.Header, req.Method, req.URL.String(), httpResp.StatusCode); err != nil {
+			httpResp.Body.Close()
+			return nil, err
+		}
+	}
+
+	// Strip any X-Gog-Fwd- prefix the companion webhook echoes back, and
+	// drop our own signature headers; anything else passes through
+	// untouched.
+	respHeader := make(http.Header, len(httpResp.Header))
+	for k, vals := range httpResp.Header {
+		if k == "X-Gog-Signature" || k == "X-Gog-Timestamp" {
+			continue
+		}
+
+		key := strings.TrimPrefix(k, "X-Gog-Fwd-")
+		for _, v := range vals {
+			respHeader.Add(key, v)
+		}
+	}
+
+	return &http.Response{
+		StatusCode:    httpResp.StatusCode,
+		Header:        respHeader,
+		Body:          httpResp.Body,
+		ContentLength: httpResp.ContentLength,
+		Request:       req,
+	}, nil
+}
+
+// streamingCanonicalPayload builds the bytes signed/verified for a streaming
+// request or response: method, target URL, and (for a response) its status
+// code. statusCode is 0 for the outgoing request, which has none yet.
+func streamingCanonicalPayload(method, url string, statusCode int) string {
+	return fmt.Sprintf("%s\n%s\n%d", method, url, statusCode)
+}
+
+// doRoundTrip performs a single attempt: serializing req (with the
+// already-buffered rawBody), sending it to the webhook, and reconstructing
+// the response.
+func (t *WebhookTransport) doRoundTrip(req *http.Request, rawBody []byte) (*http.Response, error) {
+	// Base64-encode the request body, compressing it first when
+	// t.Compression calls for it.
+	var bodyEncoded string
+
+	var bodyEncoding string
+
+	if len(rawBody) > 0 {
+		bodyBytes := rawBody
+
+		if t.shouldCompress(len(bodyBytes)) {
+			compressed, gzErr := gzipBytes(bodyBytes)
+			if gzErr != nil {
+				return nil, fmt.Errorf("gzip request body: %w", gzErr)
+			}
+
+			bodyBytes = compressed
+			bodyEncoding = CompressionGzip
+		}
+
+		bodyEncoded = base64.StdEncoding.EncodeToString(bodyBytes)
+	}
+
+	// Copy headers, preserving every value per key (e.g. repeated Accept
+	// or X-Goog-* entries), without aliasing req.Header's slices.
+	headers := make(map[string][]string, len(req.Header))
+	for k, vals := range req.Header {
+		headers[k] = append([]string(nil), vals...)
+	}
+
 	webhookReq := WebhookRequest{
-		Method:  req.Method,
-		URL:     req.URL.String(),
-		Headers: headers,
-		Body:    bodyEncoded,
+		Method:   req.Method,
+		URL:      req.URL.String(),
+		Headers:  headers,
+		Body:     bodyEncoded,
+		Encoding: bodyEncoding,
+	}
+
+	if t.Secret != "" {
+		webhookReq.Timestamp = time.Now().Unix()
 	}
 
 	payload, err := json.Marshal(webhookReq)
@@ -97,6 +533,19 @@ func (t *WebhookTransport) RoundTrip(req *http.Request) (*http.Response, error)
 
 	httpReq.Header.Set("Content-Type", "application/json")
 
+	// Deliberately not setting Accept-Encoding here: doing so would disable
+	// net/http's transparent response decompression (documented to kick in
+	// only when the caller leaves the header unset), so a webhook deployment
+	// that honored the hint would hand us a gzipped HTTP response body that
+	// verifyResponseSignature and json.Unmarshal below can't read. The inner
+	// Encoding field above already covers payload-level compression.
+
+	if t.Secret != "" {
+		sig := signPayload(t.Secret, webhookReq.Timestamp, payload)
+		httpReq.Header.Set("X-Gog-Timestamp", strconv.FormatInt(webhookReq.Timestamp, 10))
+		httpReq.Header.Set("X-Gog-Signature", "sha256="+sig)
+	}
+
 	httpResp, err := t.HTTPClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("webhook request: %w", err)
@@ -114,6 +563,13 @@ func (t *WebhookTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		return nil, &WebhookError{
 			StatusCode: httpResp.StatusCode,
 			Body:       string(respBody),
+			RetryAfter: parseRetryAfter(httpResp.Header.Get("Retry-After")),
+		}
+	}
+
+	if t.Secret != "" {
+		if err := t.verifyResponseSignature(httpResp.Header, respBody); err != nil {
+			return nil, err
 		}
 	}
 
@@ -133,10 +589,22 @@ func (t *WebhookTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		}
 	}
 
-	// Reconstruct the http.Response.
+	if responseIsGzipped(webhookResp) {
+		gunzipped, gzErr := gunzipBytes(decodedBody)
+		if gzErr != nil {
+			return nil, &CompressionError{Err: gzErr}
+		}
+
+		decodedBody = gunzipped
+	}
+
+	// Reconstruct the http.Response, preserving every value per header key
+	// (e.g. multiple Set-Cookie entries).
 	respHeader := make(http.Header, len(webhookResp.Headers))
-	for k, v := range webhookResp.Headers {
-		respHeader.Set(k, v)
+	for k, vals := range webhookResp.Headers {
+		for _, v := range vals {
+			respHeader.Add(k, v)
+		}
 	}
 
 	return &http.Response{
@@ -152,10 +620,18 @@ func gatewayWebhookURL() string {
 	return strings.TrimSpace(os.Getenv(n8nWebhookURLEnv))
 }
 
+// gatewayWebhookSecret reads and trims the N8N_GOG_WEBHOOK_SECRET environment variable.
+func gatewayWebhookSecret() string {
+	return strings.TrimSpace(os.Getenv(n8nWebhookSecretEnv))
+}
+
 // WebhookError indicates the webhook endpoint returned a non-200 status.
 type WebhookError struct {
 	StatusCode int
 	Body       string
+
+	// RetryAfter is parsed from the webhook's Retry-After header, if any.
+	RetryAfter time.Duration
 }
 
 func (e *WebhookError) Error() string {
@@ -167,3 +643,171 @@ func IsWebhookError(err error) bool {
 	var e *WebhookError
 	return errors.As(err, &e)
 }
+
+// CompressionError indicates a webhook response declared a compressed body
+// (via Encoding or Content-Encoding) that failed to decompress, distinct
+// from a WebhookError or a malformed envelope.
+type CompressionError struct {
+	Err error
+}
+
+func (e *CompressionError) Error() string {
+	return fmt.Sprintf("webhook: decompress response body: %v", e.Err)
+}
+
+func (e *CompressionError) Unwrap() error {
+	return e.Err
+}
+
+// IsCompressionError checks if the error is a CompressionError.
+func IsCompressionError(err error) bool {
+	var e *CompressionError
+	return errors.As(err, &e)
+}
+
+// ErrSignatureInvalid indicates a signed webhook response failed HMAC
+// verification, or its timestamp drifted outside the allowed window.
+var ErrSignatureInvalid = errors.New("webhook: response signature invalid")
+
+// IsSignatureError reports whether err is or wraps ErrSignatureInvalid.
+func IsSignatureError(err error) bool {
+	return errors.Is(err, ErrSignatureInvalid)
+}
+
+// ErrCircuitOpen indicates the per-host circuit breaker is open and the
+// request was failed fast without being sent.
+var ErrCircuitOpen = errors.New("webhook: circuit open")
+
+// isRetryableError reports whether err is a transient failure worth
+// retrying: a connection-level failure reaching the webhook, the outer
+// HTTP client's own deadline expiring, or a 502/503/504 from the webhook.
+// Context cancellation and non-retryable WebhookErrors (e.g. 4xx) are not.
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var we *WebhookError
+	if errors.As(err, &we) {
+		switch we.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+
+	var urlErr *url.Error
+
+	return errors.As(err, &urlErr)
+}
+
+// retryAfterFrom extracts the Retry-After duration carried by a WebhookError, if any.
+func retryAfterFrom(err error) time.Duration {
+	var we *WebhookError
+	if errors.As(err, &we) {
+		return we.RetryAfter
+	}
+
+	return 0
+}
+
+// parseRetryAfter parses a Retry-After header value, given either as a
+// number of seconds or an HTTP date. Invalid or past values yield zero.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+
+		return time.Duration(secs) * time.Second
+	}
+
+	if when, err := http.ParseTime(v); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// verifyResponseSignature checks the X-Gog-Signature header against respBody
+// using t.Secret, and rejects timestamps outside the configured window.
+// Since t.Secret is set, signing is mandatory: a response missing
+// X-Gog-Signature or X-Gog-Timestamp is rejected as ErrSignatureInvalid
+// rather than waved through, or a forger could defeat verification simply
+// by not signing its response.
+func (t *WebhookTransport) verifyResponseSignature(header http.Header, respBody []byte) error {
+	return t.verifySignedPayload(header, respBody)
+}
+
+// verifyStreamingResponseSignature checks a streaming response's
+// X-Gog-Signature against streamingCanonicalPayload(method, url, status):
+// the body itself is never buffered on this path, so unlike
+// verifyResponseSignature it can't authenticate the body's contents, only
+// that the companion webhook produced this exact status for this exact
+// request.
+func (t *WebhookTransport) verifyStreamingResponseSignature(header http.Header, method, url string, statusCode int) error {
+	return t.verifySignedPayload(header, []byte(streamingCanonicalPayload(method, url, statusCode)))
+}
+
+// verifySignedPayload is the shared signature-verification core for both
+// verifyResponseSignature and verifyStreamingResponseSignature.
+func (t *WebhookTransport) verifySignedPayload(header http.Header, payload []byte) error {
+	sigHeader := header.Get("X-Gog-Signature")
+	if sigHeader == "" {
+		return fmt.Errorf("%w: response missing X-Gog-Signature", ErrSignatureInvalid)
+	}
+
+	tsHeader := header.Get("X-Gog-Timestamp")
+	if tsHeader == "" {
+		return fmt.Errorf("%w: response missing X-Gog-Timestamp", ErrSignatureInvalid)
+	}
+
+	ts, err := strconv.ParseInt(tsHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid X-Gog-Timestamp %q", ErrSignatureInvalid, tsHeader)
+	}
+
+	drift := time.Since(time.Unix(ts, 0))
+	if drift < 0 {
+		drift = -drift
+	}
+
+	if drift > t.signatureWindow() {
+		return fmt.Errorf("%w: timestamp drift %s exceeds window %s", ErrSignatureInvalid, drift, t.signatureWindow())
+	}
+
+	const prefix = "sha256="
+
+	given := strings.TrimPrefix(sigHeader, prefix)
+
+	givenMAC, err := hex.DecodeString(given)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature %q", ErrSignatureInvalid, sigHeader)
+	}
+
+	wantMAC, err := hex.DecodeString(signPayload(t.Secret, ts, payload))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSignatureInvalid, err)
+	}
+
+	if !hmac.Equal(givenMAC, wantMAC) {
+		return fmt.Errorf("%w: signature mismatch", ErrSignatureInvalid)
+	}
+
+	return nil
+}
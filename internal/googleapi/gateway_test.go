@@ -1,6 +1,7 @@
 package googleapi
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -8,8 +9,11 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestWebhookTransport_RoundTrip_GET(t *testing.T) {
@@ -27,7 +31,7 @@ func TestWebhookTransport_RoundTrip_GET(t *testing.T) {
 
 		resp := WebhookResponse{
 			StatusCode: 200,
-			Headers:    map[string]string{"X-Test": "ok"},
+			Headers:    map[string][]string{"X-Test": {"ok"}},
 			Body:       base64.StdEncoding.EncodeToString([]byte(`{"result":"success"}`)),
 		}
 
@@ -64,8 +68,8 @@ func TestWebhookTransport_RoundTrip_GET(t *testing.T) {
 		t.Fatalf("unexpected URL: %q", received.URL)
 	}
 
-	if received.Headers["Authorization"] != "Bearer token123" {
-		t.Fatalf("expected Authorization header, got %q", received.Headers["Authorization"])
+	if got := received.Headers["Authorization"]; len(got) != 1 || got[0] != "Bearer token123" {
+		t.Fatalf("expected Authorization header, got %q", got)
 	}
 
 	if received.Body != "" {
@@ -106,7 +110,7 @@ func TestWebhookTransport_RoundTrip_POST_WithBody(t *testing.T) {
 
 		resp := WebhookResponse{
 			StatusCode: 201,
-			Headers:    map[string]string{"Content-Type": "application/json"},
+			Headers:    map[string][]string{"Content-Type": {"application/json"}},
 			Body:       base64.StdEncoding.EncodeToString([]byte(`{"id":"123"}`)),
 		}
 
@@ -263,7 +267,7 @@ func TestWebhookTransport_FallbackRawBody(t *testing.T) {
 		// Return a body that is NOT valid base64.
 		resp := WebhookResponse{
 			StatusCode: 200,
-			Headers:    map[string]string{"Content-Type": "text/plain"},
+			Headers:    map[string][]string{"Content-Type": {"text/plain"}},
 			Body:       "this is not base64!!!",
 		}
 
@@ -299,3 +303,935 @@ func TestWebhookTransport_FallbackRawBody(t *testing.T) {
 		t.Fatalf("expected raw fallback body, got %q", string(respBody))
 	}
 }
+
+func TestWebhookTransport_Signing_MissingSecretSkipsSigning(t *testing.T) {
+	var received WebhookRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Gog-Signature") != "" {
+			t.Errorf("expected no signature header when secret is unset")
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+
+		resp := WebhookResponse{
+			StatusCode: 200,
+			Body:       base64.StdEncoding.EncodeToString([]byte(`{"ok":true}`)),
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if received.Timestamp != 0 {
+		t.Fatalf("expected no timestamp without a secret, got %d", received.Timestamp)
+	}
+}
+
+func TestWebhookTransport_Signing_ValidSignatureRoundTrip(t *testing.T) {
+	const secret = "shh-its-a-secret"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+
+		ts := r.Header.Get("X-Gog-Timestamp")
+		if ts == "" {
+			t.Fatalf("expected X-Gog-Timestamp header")
+		}
+
+		sig := r.Header.Get("X-Gog-Signature")
+		if sig == "" {
+			t.Fatalf("expected X-Gog-Signature header")
+		}
+
+		tsInt, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			t.Fatalf("parse timestamp: %v", err)
+		}
+
+		want := "sha256=" + signPayload(secret, tsInt, body)
+		if sig != want {
+			t.Fatalf("signature mismatch: got %q want %q", sig, want)
+		}
+
+		respBody, err := json.Marshal(WebhookResponse{
+			StatusCode: 200,
+			Body:       base64.StdEncoding.EncodeToString([]byte(`{"ok":true}`)),
+		})
+		if err != nil {
+			t.Fatalf("marshal response: %v", err)
+		}
+
+		respTS := time.Now().Unix()
+
+		w.Header().Set("X-Gog-Timestamp", strconv.FormatInt(respTS, 10))
+		w.Header().Set("X-Gog-Signature", "sha256="+signPayload(secret, respTS, respBody))
+		_, _ = w.Write(respBody)
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	tr.Secret = secret
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read response body: %v", err)
+	}
+
+	if string(body) != `{"ok":true}` {
+		t.Fatalf("unexpected response body: %q", string(body))
+	}
+}
+
+func TestWebhookTransport_Signing_TamperedPayloadRejected(t *testing.T) {
+	const secret = "shh-its-a-secret"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respBody, err := json.Marshal(WebhookResponse{
+			StatusCode: 200,
+			Body:       base64.StdEncoding.EncodeToString([]byte(`{"ok":true}`)),
+		})
+		if err != nil {
+			t.Fatalf("marshal response: %v", err)
+		}
+
+		respTS := time.Now().Unix()
+
+		// Sign a different payload than the one actually written, simulating
+		// an attacker tampering with the response body in flight.
+		w.Header().Set("X-Gog-Timestamp", strconv.FormatInt(respTS, 10))
+		w.Header().Set("X-Gog-Signature", "sha256="+signPayload(secret, respTS, []byte("tampered")))
+		_, _ = w.Write(respBody)
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	tr.Secret = secret
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	_, err = tr.RoundTrip(req)
+	if err == nil {
+		t.Fatalf("expected error for tampered response signature")
+	}
+
+	if !IsSignatureError(err) {
+		t.Fatalf("expected signature error, got %T: %v", err, err)
+	}
+}
+
+func TestWebhookTransport_Signing_ExpiredTimestampRejected(t *testing.T) {
+	const secret = "shh-its-a-secret"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respBody, err := json.Marshal(WebhookResponse{
+			StatusCode: 200,
+			Body:       base64.StdEncoding.EncodeToString([]byte(`{"ok":true}`)),
+		})
+		if err != nil {
+			t.Fatalf("marshal response: %v", err)
+		}
+
+		respTS := time.Now().Add(-10 * time.Minute).Unix()
+
+		w.Header().Set("X-Gog-Timestamp", strconv.FormatInt(respTS, 10))
+		w.Header().Set("X-Gog-Signature", "sha256="+signPayload(secret, respTS, respBody))
+		_, _ = w.Write(respBody)
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	tr.Secret = secret
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	_, err = tr.RoundTrip(req)
+	if err == nil {
+		t.Fatalf("expected error for expired timestamp")
+	}
+
+	if !IsSignatureError(err) {
+		t.Fatalf("expected signature error, got %T: %v", err, err)
+	}
+}
+
+func TestWebhookTransport_Signing_UnsignedResponseRejectedWhenSecretSet(t *testing.T) {
+	const secret = "shh-its-a-secret"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respBody, err := json.Marshal(WebhookResponse{
+			StatusCode: 200,
+			Body:       base64.StdEncoding.EncodeToString([]byte(`{"ok":true}`)),
+		})
+		if err != nil {
+			t.Fatalf("marshal response: %v", err)
+		}
+
+		// Simulate a forger skipping the signature headers entirely.
+		_, _ = w.Write(respBody)
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	tr.Secret = secret
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	_, err = tr.RoundTrip(req)
+	if err == nil {
+		t.Fatalf("expected error for unsigned response when a secret is configured")
+	}
+
+	if !IsSignatureError(err) {
+		t.Fatalf("expected signature error, got %T: %v", err, err)
+	}
+}
+
+func TestWebhookTransport_MultiValueResponseHeaders_SetCookieSurvives(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := WebhookResponse{
+			StatusCode: 200,
+			Headers: map[string][]string{
+				"Set-Cookie": {"a=1; Path=/", "b=2; Path=/"},
+			},
+			Body: base64.StdEncoding.EncodeToString([]byte(`{"ok":true}`)),
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("encode response: %v", err)
+		}
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	cookies := resp.Header.Values("Set-Cookie")
+	if len(cookies) != 2 || cookies[0] != "a=1; Path=/" || cookies[1] != "b=2; Path=/" {
+		t.Fatalf("expected both Set-Cookie values to survive, got %v", cookies)
+	}
+}
+
+func TestWebhookTransport_MultiValueRequestHeaders_Survive(t *testing.T) {
+	var received WebhookRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+
+		resp := WebhookResponse{StatusCode: 200}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("encode response: %v", err)
+		}
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	req.Header.Add("X-Trace", "a")
+	req.Header.Add("X-Trace", "b")
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	got := received.Headers["X-Trace"]
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("expected both X-Trace values to be transmitted, got %v", got)
+	}
+}
+
+func TestWebhookTransport_Compression_LargeBodyTransmittedCompressed(t *testing.T) {
+	var received WebhookRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+
+		resp := WebhookResponse{StatusCode: 200}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("encode response: %v", err)
+		}
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	tr.Compression = CompressionAuto
+
+	reqBody := strings.Repeat("a", 2048)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://www.googleapis.com/create", strings.NewReader(reqBody))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if received.Encoding != CompressionGzip {
+		t.Fatalf("expected gzip encoding, got %q", received.Encoding)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(received.Body)
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+
+	gunzipped, err := gunzipBytes(decoded)
+	if err != nil {
+		t.Fatalf("gunzip: %v", err)
+	}
+
+	if string(gunzipped) != reqBody {
+		t.Fatalf("unexpected decompressed body: %q", string(gunzipped))
+	}
+}
+
+func TestWebhookTransport_Compression_GzippedResponseDecoded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressed, err := gzipBytes([]byte(`{"result":"success"}`))
+		if err != nil {
+			t.Fatalf("gzip: %v", err)
+		}
+
+		resp := WebhookResponse{
+			StatusCode: 200,
+			Encoding:   CompressionGzip,
+			Body:       base64.StdEncoding.EncodeToString(compressed),
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("encode response: %v", err)
+		}
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read response body: %v", err)
+	}
+
+	if string(body) != `{"result":"success"}` {
+		t.Fatalf("unexpected response body: %q", string(body))
+	}
+}
+
+func TestWebhookTransport_Compression_MalformedGzipIsTypedError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := WebhookResponse{
+			StatusCode: 200,
+			Encoding:   CompressionGzip,
+			Body:       base64.StdEncoding.EncodeToString([]byte("not actually gzip")),
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("encode response: %v", err)
+		}
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	_, err = tr.RoundTrip(req)
+	if err == nil {
+		t.Fatalf("expected error for malformed gzip body")
+	}
+
+	if !IsCompressionError(err) {
+		t.Fatalf("expected CompressionError, got %T: %v", err, err)
+	}
+
+	if IsWebhookError(err) {
+		t.Fatalf("malformed gzip should not be a WebhookError")
+	}
+}
+
+func TestWebhookTransport_Retry_FlappingThenSucceeds(t *testing.T) {
+	var attempts int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("unavailable"))
+
+			return
+		}
+
+		resp := WebhookResponse{
+			StatusCode: 200,
+			Body:       base64.StdEncoding.EncodeToString([]byte(`{"ok":true}`)),
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	tr.Retry = RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestWebhookTransport_Retry_NoRetryOn4xx(t *testing.T) {
+	var attempts int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("bad request"))
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	tr.Retry = RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	_, err = tr.RoundTrip(req)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("expected exactly 1 attempt for a 4xx, got %d", got)
+	}
+}
+
+func TestWebhookTransport_Retry_NoRetryOnNonIdempotentByDefault(t *testing.T) {
+	var attempts int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("unavailable"))
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	tr.Retry = RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://www.googleapis.com/create", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	_, err = tr.RoundTrip(req)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("expected exactly 1 attempt for a POST without WithRetryAllowed, got %d", got)
+	}
+}
+
+func TestWebhookTransport_Retry_PostRetriedWhenOptedIn(t *testing.T) {
+	var attempts int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+
+		if n < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("unavailable"))
+
+			return
+		}
+
+		resp := WebhookResponse{StatusCode: 200}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	tr.Retry = RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	ctx := WithRetryAllowed(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://www.googleapis.com/create", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailuresAndProbesAfterCooldown(t *testing.T) {
+	b := NewCircuitBreaker(BreakerPolicy{
+		FailureThreshold: 2,
+		Window:           time.Minute,
+		Cooldown:         10 * time.Millisecond,
+	})
+
+	const host = "www.googleapis.com"
+
+	if !b.Allow(host) {
+		t.Fatalf("expected breaker to start closed")
+	}
+
+	b.RecordFailure(host)
+
+	if !b.Allow(host) {
+		t.Fatalf("expected single failure not to open breaker")
+	}
+
+	b.RecordFailure(host)
+
+	if b.Allow(host) {
+		t.Fatalf("expected breaker to be open after threshold failures")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if !b.Allow(host) {
+		t.Fatalf("expected a half-open probe to be allowed after cooldown")
+	}
+
+	if b.Allow(host) {
+		t.Fatalf("expected only a single probe while half-open")
+	}
+
+	b.RecordSuccess(host)
+
+	if !b.Allow(host) {
+		t.Fatalf("expected breaker to close after a successful probe")
+	}
+}
+
+func TestWebhookTransport_CircuitBreaker_FailsFastWhenOpen(t *testing.T) {
+	var attempts int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("unavailable"))
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport(srv.URL)
+	tr.Retry = RetryPolicy{MaxAttempts: 1}
+	tr.Breaker = NewCircuitBreaker(BreakerPolicy{
+		FailureThreshold: 1,
+		Window:           time.Minute,
+		Cooldown:         time.Minute,
+	})
+
+	newReq := func() *http.Request {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/test", nil)
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+
+		return req
+	}
+
+	if _, err := tr.RoundTrip(newReq()); err == nil {
+		t.Fatalf("expected error from first request")
+	}
+
+	_, err := tr.RoundTrip(newReq())
+	if err == nil {
+		t.Fatalf("expected circuit-open error from second request")
+	}
+
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("expected the webhook not to be called once the breaker is open, got %d calls", got)
+	}
+}
+
+func TestWebhookTransport_Streaming_DownloadNotFullyBuffered(t *testing.T) {
+	const totalSize = 10 * 1024 * 1024 // 10MB
+	const chunkSize = 256 * 1024
+
+	firstChunkRead := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Gog-Target-URL"); got != "https://www.googleapis.com/download/file.bin" {
+			t.Errorf("unexpected X-Gog-Target-URL: %q", got)
+		}
+
+		flusher, _ := w.(http.Flusher)
+		chunk := bytes.Repeat([]byte{'a'}, chunkSize)
+
+		for written := 0; written < totalSize; written += chunkSize {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+
+			// Hold back the rest of the body until the client has read the
+			// first chunk from resp.Body.
+			if written == 0 {
+				select {
+				case <-firstChunkRead:
+				case <-time.After(5 * time.Second):
+					t.Errorf("expected RoundTrip to return before the whole body was written")
+					return
+				}
+			}
+		}
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport("https://n8n.example.com/webhook/unused")
+	tr.StreamingWebhookURL = srv.URL
+	tr.StreamingPaths = []string{"/download/"}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://www.googleapis.com/download/file.bin", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	buf := make([]byte, 64*1024)
+
+	var total, reads int
+
+	for {
+		n, err := resp.Body.Read(buf)
+		total += n
+
+		if n > 0 {
+			reads++
+
+			if reads == 1 {
+				close(firstChunkRead)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+	}
+
+	// The server only writes past its first chunk once the client has read
+	// from resp.Body, so receiving all totalSize bytes shows RoundTrip
+	// returned before the body was fully written, i.e. without buffering it.
+	if total != totalSize {
+		t.Fatalf("expected %d bytes, got %d", totalSize, total)
+	}
+
+	if reads < 2 {
+		t.Fatalf("expected the body to arrive over multiple reads, got %d", reads)
+	}
+}
+
+func TestWebhookTransport_Streaming_ChunkedUploadUnknownContentLength(t *testing.T) {
+	var receivedContentLength int64 = -2
+
+	var gotTargetURL string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedContentLength = r.ContentLength
+		gotTargetURL = r.Header.Get("X-Gog-Target-URL")
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+
+		if string(body) != "streamed-upload-body" {
+			t.Fatalf("unexpected body: %q", body)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport("https://n8n.example.com/webhook/unused")
+	tr.StreamingWebhookURL = srv.URL
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		_, _ = pw.Write([]byte("streamed-upload-body"))
+		_ = pw.Close()
+	}()
+
+	ctx := WithStreaming(context.Background())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://www.googleapis.com/upload/file.bin", pr)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	req.ContentLength = -1
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	if receivedContentLength > 0 {
+		t.Fatalf("expected an unknown content length to be preserved, got %d", receivedContentLength)
+	}
+
+	if gotTargetURL != "https://www.googleapis.com/upload/file.bin" {
+		t.Fatalf("unexpected target URL: %q", gotTargetURL)
+	}
+}
+
+func TestWebhookTransport_Streaming_SignsRequestAndVerifiesResponse(t *testing.T) {
+	const secret = "shh-its-a-secret"
+	const targetURL = "https://www.googleapis.com/download/file.bin"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts := r.Header.Get("X-Gog-Timestamp")
+		if ts == "" {
+			t.Fatalf("expected X-Gog-Timestamp header on streaming request")
+		}
+
+		sig := r.Header.Get("X-Gog-Signature")
+		if sig == "" {
+			t.Fatalf("expected X-Gog-Signature header on streaming request")
+		}
+
+		tsInt, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			t.Fatalf("parse timestamp: %v", err)
+		}
+
+		want := "sha256=" + signPayload(secret, tsInt, []byte(streamingCanonicalPayload(http.MethodGet, targetURL, 0)))
+		if sig != want {
+			t.Fatalf("request signature mismatch: got %q want %q", sig, want)
+		}
+
+		respTS := time.Now().Unix()
+		respSig := signPayload(secret, respTS, []byte(streamingCanonicalPayload(http.MethodGet, targetURL, http.StatusOK)))
+
+		w.Header().Set("X-Gog-Timestamp", strconv.FormatInt(respTS, 10))
+		w.Header().Set("X-Gog-Signature", "sha256="+respSig)
+		_, _ = w.Write([]byte("streamed-download-body"))
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport("https://n8n.example.com/webhook/unused")
+	tr.Secret = secret
+	tr.StreamingWebhookURL = srv.URL
+
+	req, err := http.NewRequestWithContext(WithStreaming(context.Background()), http.MethodGet, targetURL, nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("X-Gog-Signature"); got != "" {
+		t.Fatalf("expected signature header stripped from the returned response, got %q", got)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read response body: %v", err)
+	}
+
+	if string(body) != "streamed-download-body" {
+		t.Fatalf("unexpected response body: %q", string(body))
+	}
+}
+
+func TestWebhookTransport_Streaming_TamperedResponseSignatureRejected(t *testing.T) {
+	const secret = "shh-its-a-secret"
+	const targetURL = "https://www.googleapis.com/download/file.bin"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respTS := time.Now().Unix()
+
+		// Sign a different status than the one actually returned, simulating
+		// a forger tampering with the streamed response in flight.
+		respSig := signPayload(secret, respTS, []byte(streamingCanonicalPayload(http.MethodGet, targetURL, http.StatusTeapot)))
+
+		w.Header().Set("X-Gog-Timestamp", strconv.FormatInt(respTS, 10))
+		w.Header().Set("X-Gog-Signature", "sha256="+respSig)
+		_, _ = w.Write([]byte("streamed-download-body"))
+	}))
+
+	defer srv.Close()
+
+	tr := NewWebhookTransport("https://n8n.example.com/webhook/unused")
+	tr.Secret = secret
+	tr.StreamingWebhookURL = srv.URL
+
+	req, err := http.NewRequestWithContext(WithStreaming(context.Background()), http.MethodGet, targetURL, nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	_, err = tr.RoundTrip(req)
+	if err == nil {
+		t.Fatalf("expected error for tampered streaming response signature")
+	}
+
+	if !IsSignatureError(err) {
+		t.Fatalf("expected signature error, got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,85 @@
+package googleapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DefaultProviderName is used when no --provider flag or GOG_PROVIDER env
+// var is set, preserving the existing Google + n8n behavior.
+const DefaultProviderName = "google"
+
+// ProviderConfig carries the settings a Provider needs to build its transport.
+type ProviderConfig struct {
+	// WebhookURL is the n8n (or equivalent) gateway endpoint the provider's
+	// transport forwards requests through.
+	WebhookURL string
+
+	// Secret, when set, enables HMAC signing on the provider's transport.
+	Secret string
+}
+
+// Provider is an identity/API provider pluggable into the gateway, the way
+// dex exposes separate "github" and "oidc" connectors behind one interface.
+// Implementations register themselves via RegisterProvider, typically from
+// an init() function.
+type Provider interface {
+	// Name is the provider's unique registry key (e.g. "google", "github").
+	Name() string
+
+	// NewTransport builds the http.RoundTripper used to reach this
+	// provider's API through the gateway.
+	NewTransport(cfg ProviderConfig) http.RoundTripper
+
+	// AuthURL and TokenURL are this provider's OAuth2 endpoints.
+	AuthURL() string
+	TokenURL() string
+
+	// Scopes lists the OAuth2 scopes this provider's client requests.
+	Scopes() []string
+}
+
+var providers = make(map[string]Provider)
+
+// RegisterProvider adds p to the registry under its Name(), overwriting any
+// provider already registered with the same name.
+func RegisterProvider(p Provider) {
+	providers[strings.ToLower(p.Name())] = p
+}
+
+// ResolveProvider looks up a registered provider by name, case-insensitively.
+func ResolveProvider(name string) (Provider, error) {
+	p, ok := providers[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("googleapi: unknown provider %q (known: %s)", name, strings.Join(ProviderNames(), ", "))
+	}
+
+	return p, nil
+}
+
+// ProviderNames returns the sorted names of all registered providers.
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// WebhookURLFromEnv reads and trims the N8N_GOG_WEBHOOK_URL environment
+// variable: the shared n8n gateway endpoint every provider's transport is
+// built against.
+func WebhookURLFromEnv() string {
+	return gatewayWebhookURL()
+}
+
+// WebhookSecretFromEnv reads and trims the N8N_GOG_WEBHOOK_SECRET
+// environment variable used to HMAC-sign requests sent through the gateway.
+func WebhookSecretFromEnv() string {
+	return gatewayWebhookSecret()
+}
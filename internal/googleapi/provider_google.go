@@ -0,0 +1,31 @@
+package googleapi
+
+import "net/http"
+
+// googlePeopleScope is the OAuth2 scope used by the People API client.
+const googlePeopleScope = "https://www.googleapis.com/auth/contacts"
+
+// googleProvider is the default Provider, wrapping the existing n8n
+// webhook transport so current behavior and tests are unchanged.
+type googleProvider struct{}
+
+func init() {
+	RegisterProvider(googleProvider{})
+}
+
+func (googleProvider) Name() string { return DefaultProviderName }
+
+func (googleProvider) NewTransport(cfg ProviderConfig) http.RoundTripper {
+	t := NewWebhookTransport(cfg.WebhookURL)
+	if cfg.Secret != "" {
+		t.Secret = cfg.Secret
+	}
+
+	return t
+}
+
+func (googleProvider) AuthURL() string { return "https://accounts.google.com/o/oauth2/v2/auth" }
+
+func (googleProvider) TokenURL() string { return "https://oauth2.googleapis.com/token" }
+
+func (googleProvider) Scopes() []string { return []string{googlePeopleScope} }
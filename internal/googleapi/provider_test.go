@@ -0,0 +1,57 @@
+package googleapi
+
+import "testing"
+
+func TestResolveProvider_DefaultGoogle(t *testing.T) {
+	p, err := ResolveProvider(DefaultProviderName)
+	if err != nil {
+		t.Fatalf("ResolveProvider: %v", err)
+	}
+
+	if p.Name() != "google" {
+		t.Fatalf("expected google provider, got %q", p.Name())
+	}
+
+	if len(p.Scopes()) == 0 {
+		t.Fatalf("expected at least one scope")
+	}
+}
+
+func TestResolveProvider_CaseInsensitiveAndTrimmed(t *testing.T) {
+	p, err := ResolveProvider("  GOOGLE  ")
+	if err != nil {
+		t.Fatalf("ResolveProvider: %v", err)
+	}
+
+	if p.Name() != "google" {
+		t.Fatalf("expected google provider, got %q", p.Name())
+	}
+}
+
+func TestResolveProvider_Unknown(t *testing.T) {
+	if _, err := ResolveProvider("does-not-exist"); err == nil {
+		t.Fatalf("expected error for unknown provider")
+	}
+}
+
+func TestGoogleProvider_NewTransport_UsesWebhookURLAndSecret(t *testing.T) {
+	p, err := ResolveProvider(DefaultProviderName)
+	if err != nil {
+		t.Fatalf("ResolveProvider: %v", err)
+	}
+
+	rt := p.NewTransport(ProviderConfig{WebhookURL: "https://n8n.example.com/webhook/abc", Secret: "shh"})
+
+	tr, ok := rt.(*WebhookTransport)
+	if !ok {
+		t.Fatalf("expected *WebhookTransport, got %T", rt)
+	}
+
+	if tr.WebhookURL != "https://n8n.example.com/webhook/abc" {
+		t.Fatalf("unexpected webhook URL: %q", tr.WebhookURL)
+	}
+
+	if tr.Secret != "shh" {
+		t.Fatalf("unexpected secret: %q", tr.Secret)
+	}
+}
@@ -0,0 +1,242 @@
+package googleapi
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryPolicy configures WebhookTransport's retry attempts. Zero-value
+// fields fall back to the matching DefaultRetryPolicy value.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+
+	// BaseDelay is the backoff base for the first retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the backoff delay between attempts.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used for unset RetryPolicy fields.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+	}
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+
+	return DefaultRetryPolicy().MaxAttempts
+}
+
+func (p RetryPolicy) baseDelay() time.Duration {
+	if p.BaseDelay > 0 {
+		return p.BaseDelay
+	}
+
+	return DefaultRetryPolicy().BaseDelay
+}
+
+func (p RetryPolicy) maxDelay() time.Duration {
+	if p.MaxDelay > 0 {
+		return p.MaxDelay
+	}
+
+	return DefaultRetryPolicy().MaxDelay
+}
+
+// backoff computes a full-jitter exponential delay for the given 0-indexed
+// attempt: a uniform random duration between 0 and min(cap, base*2^attempt).
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	maxD := p.maxDelay()
+
+	d := p.baseDelay()
+	for i := 0; i < attempt; i++ {
+		d *= 2
+		if d > maxD || d <= 0 {
+			d = maxD
+			break
+		}
+	}
+
+	if d > maxD {
+		d = maxD
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// BreakerPolicy configures a CircuitBreaker. Zero-value fields fall back to
+// the matching DefaultBreakerPolicy value.
+type BreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failures within
+	// Window that trips the breaker open.
+	FailureThreshold int
+
+	// Window is the span, measured from the first failure of a run, within
+	// which FailureThreshold consecutive failures must land to trip the
+	// breaker; a failure after the span ends starts a new run.
+	Window time.Duration
+
+	// Cooldown is how long the breaker stays open before allowing a
+	// single half-open probe request through.
+	Cooldown time.Duration
+}
+
+// DefaultBreakerPolicy returns the breaker policy used for unset BreakerPolicy fields.
+func DefaultBreakerPolicy() BreakerPolicy {
+	return BreakerPolicy{
+		FailureThreshold: 5,
+		Window:           30 * time.Second,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+func (p BreakerPolicy) failureThreshold() int {
+	if p.FailureThreshold > 0 {
+		return p.FailureThreshold
+	}
+
+	return DefaultBreakerPolicy().FailureThreshold
+}
+
+func (p BreakerPolicy) window() time.Duration {
+	if p.Window > 0 {
+		return p.Window
+	}
+
+	return DefaultBreakerPolicy().Window
+}
+
+func (p BreakerPolicy) cooldown() time.Duration {
+	if p.Cooldown > 0 {
+		return p.Cooldown
+	}
+
+	return DefaultBreakerPolicy().Cooldown
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// hostState tracks a single host's failure history and breaker state.
+type hostState struct {
+	state       breakerState
+	failures    int
+	windowStart time.Time
+	openedAt    time.Time
+}
+
+// CircuitBreaker is a per-host circuit breaker: it closes by default, opens
+// after Policy.FailureThreshold consecutive failures land within
+// Policy.Window, and after Policy.Cooldown allows a single half-open probe
+// through before fully closing again on success or re-opening on failure.
+type CircuitBreaker struct {
+	Policy BreakerPolicy
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// NewCircuitBreaker creates a CircuitBreaker with the given policy.
+func NewCircuitBreaker(policy BreakerPolicy) *CircuitBreaker {
+	return &CircuitBreaker{
+		Policy: policy,
+		hosts:  make(map[string]*hostState),
+	}
+}
+
+func (b *CircuitBreaker) hostState(host string) *hostState {
+	hs, ok := b.hosts[host]
+	if !ok {
+		hs = &hostState{}
+		b.hosts[host] = hs
+	}
+
+	return hs
+}
+
+// Allow reports whether a request to host may proceed. While open and
+// within the cooldown, all requests are rejected; once the cooldown has
+// elapsed, exactly one probe request is allowed through as half-open.
+func (b *CircuitBreaker) Allow(host string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hs := b.hostState(host)
+
+	switch hs.state {
+	case breakerOpen:
+		if time.Since(hs.openedAt) < b.Policy.cooldown() {
+			return false
+		}
+
+		hs.state = breakerHalfOpen
+
+		return true
+	case breakerHalfOpen:
+		// A probe is already in flight; hold everyone else off until it resolves.
+		return false
+	default:
+		return true
+	}
+}
+
+// RecordSuccess closes the breaker for host and resets its failure history.
+func (b *CircuitBreaker) RecordSuccess(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hs := b.hostState(host)
+	hs.state = breakerClosed
+	hs.failures = 0
+}
+
+// RecordFailure records a failed request against host, opening the breaker
+// once Policy.FailureThreshold consecutive failures land within
+// Policy.Window, or immediately re-opening it if the failing request was
+// the half-open probe.
+func (b *CircuitBreaker) RecordFailure(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hs := b.hostState(host)
+
+	if hs.state == breakerHalfOpen {
+		hs.state = breakerOpen
+		hs.openedAt = time.Now()
+		hs.failures = 0
+
+		return
+	}
+
+	now := time.Now()
+	if hs.failures == 0 || now.Sub(hs.windowStart) > b.Policy.window() {
+		hs.windowStart = now
+		hs.failures = 0
+	}
+
+	hs.failures++
+
+	if hs.failures >= b.Policy.failureThreshold() {
+		hs.state = breakerOpen
+		hs.openedAt = now
+	}
+}
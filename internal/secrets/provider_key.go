@@ -0,0 +1,20 @@
+package secrets
+
+import (
+	"fmt"
+
+	"github.com/bilbo-22/gogcli-gateway/internal/googleapi"
+)
+
+// NamespacedTokenKey builds the keyring key for an account under provider,
+// so SetToken/GetToken/ParseTokenKey don't collide when the same email is
+// authenticated against more than one provider (e.g. Google and GitHub).
+// The default provider keeps the legacy unprefixed "token:<email>" shape so
+// existing keyring entries and ParseTokenKey behavior are unchanged.
+func NamespacedTokenKey(provider, email string) string {
+	if provider == "" || provider == googleapi.DefaultProviderName {
+		return fmt.Sprintf("token:%s", email)
+	}
+
+	return fmt.Sprintf("token:%s:%s", provider, email)
+}
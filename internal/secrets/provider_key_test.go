@@ -0,0 +1,17 @@
+package secrets
+
+import "testing"
+
+func TestNamespacedTokenKey(t *testing.T) {
+	if got := NamespacedTokenKey("google", "a@b.com"); got != "token:a@b.com" {
+		t.Fatalf("expected legacy unprefixed key for the default provider, got %q", got)
+	}
+
+	if got := NamespacedTokenKey("", "a@b.com"); got != "token:a@b.com" {
+		t.Fatalf("expected legacy unprefixed key for an empty provider, got %q", got)
+	}
+
+	if got := NamespacedTokenKey("github", "a@b.com"); got != "token:github:a@b.com" {
+		t.Fatalf("expected provider-namespaced key, got %q", got)
+	}
+}
@@ -0,0 +1,209 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+const (
+	keyringBackendEnv  = "GOG_KEYRING_BACKEND"
+	keyringPasswordEnv = "GOG_KEYRING_PASSWORD"
+
+	keyringBackendSourceDefault = "default"
+	keyringBackendSourceEnv     = "env"
+
+	keyringServiceName = "gogcli-gateway"
+)
+
+// Token is the subset of OAuth2 credentials persisted in the keyring.
+type Token struct {
+	RefreshToken string
+	AccessToken  string
+	Expiry       time.Time
+}
+
+// KeyringStore persists Tokens in an OS or file-backed keyring, namespaced
+// by provider so the same email authenticated against more than one
+// provider doesn't collide. An empty provider and the default Google
+// provider ("google") both keep the legacy unprefixed key shape; see
+// NamespacedTokenKey.
+type KeyringStore struct {
+	ring     keyring.Keyring
+	provider string
+}
+
+// OpenKeyringStore opens the OS keyring (or the file-backed fallback,
+// depending on GOG_KEYRING_BACKEND) and returns a KeyringStore namespaced
+// to provider.
+func OpenKeyringStore(provider string) (*KeyringStore, error) {
+	info, err := ResolveKeyringBackendInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	backends, err := allowedBackends(info)
+	if err != nil {
+		return nil, err
+	}
+
+	ring, err := keyring.Open(keyring.Config{
+		ServiceName:      keyringServiceName,
+		AllowedBackends:  backends,
+		FileDir:          "~/." + keyringServiceName + "/keyring",
+		FilePasswordFunc: fileKeyringPasswordFunc(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open keyring: %w", err)
+	}
+
+	return &KeyringStore{ring: ring, provider: provider}, nil
+}
+
+func (s *KeyringStore) tokenKey(email string) string {
+	return NamespacedTokenKey(s.provider, email)
+}
+
+// SetToken stores token under email, namespaced to s.provider.
+func (s *KeyringStore) SetToken(email string, token Token) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errors.New("secrets: email is required")
+	}
+
+	if token.RefreshToken == "" {
+		return errors.New("secrets: refresh token is required")
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("marshal token: %w", err)
+	}
+
+	if err := s.ring.Set(keyring.Item{
+		Key:  s.tokenKey(email),
+		Data: data,
+	}); err != nil {
+		return fmt.Errorf("store token: %w", err)
+	}
+
+	return nil
+}
+
+// GetToken retrieves the token stored for email under s.provider.
+func (s *KeyringStore) GetToken(email string) (Token, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return Token{}, errors.New("secrets: email is required")
+	}
+
+	item, err := s.ring.Get(s.tokenKey(email))
+	if err != nil {
+		return Token{}, fmt.Errorf("get token: %w", err)
+	}
+
+	var token Token
+	if err := json.Unmarshal(item.Data, &token); err != nil {
+		return Token{}, fmt.Errorf("unmarshal token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ParseTokenKey extracts the email (or, for a namespaced key, the
+// "provider:email" suffix) from a keyring key built by NamespacedTokenKey.
+// It reports false for keys without a non-empty suffix.
+func ParseTokenKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, "token:")
+	if !ok {
+		return "", false
+	}
+
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return "", false
+	}
+
+	return rest, true
+}
+
+// fileKeyringPasswordFuncFrom returns a password-prompt function for the
+// file keyring backend: secret when set, a terminal prompt when isTerminal,
+// or an error otherwise.
+func fileKeyringPasswordFuncFrom(secret string, isTerminal bool) func(string) (string, error) {
+	if secret != "" {
+		return func(string) (string, error) {
+			return secret, nil
+		}
+	}
+
+	if isTerminal {
+		return keyring.TerminalPrompt
+	}
+
+	return func(string) (string, error) {
+		return "", fmt.Errorf("secrets: no keyring password available (set %s or run interactively)", keyringPasswordEnv)
+	}
+}
+
+// fileKeyringPasswordFunc builds the file backend's password prompt from
+// GOG_KEYRING_PASSWORD, falling back to an interactive terminal prompt.
+func fileKeyringPasswordFunc() func(string) (string, error) {
+	secret := strings.TrimSpace(os.Getenv(keyringPasswordEnv))
+
+	return fileKeyringPasswordFuncFrom(secret, stdinIsTerminal())
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal, as
+// opposed to a pipe or redirected file.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// KeyringBackendInfo describes the resolved keyring backend preference and
+// where it came from.
+type KeyringBackendInfo struct {
+	Value  string
+	Source string
+}
+
+// ResolveKeyringBackendInfo reads GOG_KEYRING_BACKEND, normalizing its
+// value, and reports whether it came from the environment or the default.
+func ResolveKeyringBackendInfo() (KeyringBackendInfo, error) {
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv(keyringBackendEnv))); v != "" {
+		return KeyringBackendInfo{Value: v, Source: keyringBackendSourceEnv}, nil
+	}
+
+	return KeyringBackendInfo{Value: "auto", Source: keyringBackendSourceDefault}, nil
+}
+
+// errInvalidKeyringBackend indicates GOG_KEYRING_BACKEND named a backend
+// allowedBackends doesn't recognize.
+var errInvalidKeyringBackend = errors.New("secrets: invalid keyring backend")
+
+// allowedBackends translates a KeyringBackendInfo into the keyring.BackendType
+// list passed to keyring.Open: nil (let the library auto-detect) for "" or
+// "auto", a single explicit backend for "keychain"/"file", or an error for
+// anything else.
+func allowedBackends(info KeyringBackendInfo) ([]keyring.BackendType, error) {
+	switch strings.ToLower(strings.TrimSpace(info.Value)) {
+	case "", "auto":
+		return nil, nil
+	case "keychain":
+		return []keyring.BackendType{keyring.KeychainBackend}, nil
+	case "file":
+		return []keyring.BackendType{keyring.FileBackend}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errInvalidKeyringBackend, info.Value)
+	}
+}